@@ -0,0 +1,135 @@
+// Package backend selects and constructs the language model the DM runs
+// against, so a hosted provider can be swapped for a local one with a flag
+// instead of an edit to main.go.
+package backend
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"charm.land/fantasy"
+	"charm.land/fantasy/providers/anthropic"
+	"charm.land/fantasy/providers/google"
+	"charm.land/fantasy/providers/kronk"
+	"charm.land/fantasy/providers/ollama"
+	"charm.land/fantasy/providers/openai"
+	"github.com/ardanlabs/kronk/sdk/kronk/model"
+)
+
+// Default is the backend used when --backend and DND_BACKEND are both unset.
+const Default = "kronk"
+
+// kronkModelURL is the local GGUF weights kronk loads. It has no equivalent
+// in the hosted backends, which name their model per request instead.
+const kronkModelURL = "Qwen/Qwen3-8B-GGUF/Qwen3-8B-Q5_K_M.gguf"
+
+// Factory builds the fantasy.Provider for one backend and names the model
+// to request from it. Each factory reads its own environment and returns an
+// error rather than panicking if required configuration is missing.
+type Factory func() (provider fantasy.Provider, model string, err error)
+
+var factories = map[string]Factory{
+	"kronk":     kronkFactory,
+	"openai":    openaiFactory,
+	"anthropic": anthropicFactory,
+	"google":    googleFactory,
+	"ollama":    ollamaFactory,
+}
+
+// Names lists the registered backend names in sorted order, for flag usage
+// text and error messages.
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Build resolves the named backend into a ready fantasy.LanguageModel and a
+// cleanup func the caller should defer. cleanup is always non-nil, even on
+// error, so callers can defer it before checking err.
+func Build(ctx context.Context, name string) (fantasy.LanguageModel, func(), error) {
+	noop := func() {}
+
+	factory, ok := factories[name]
+	if !ok {
+		return nil, noop, fmt.Errorf("unknown backend %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+
+	provider, modelName, err := factory()
+	if err != nil {
+		return nil, noop, fmt.Errorf("%s provider: %w", name, err)
+	}
+
+	cleanup := func() {
+		if c, ok := provider.(interface{ Close(context.Context) error }); ok {
+			c.Close(context.Background())
+		}
+	}
+
+	llm, err := provider.LanguageModel(ctx, modelName)
+	if err != nil {
+		cleanup()
+		return nil, noop, fmt.Errorf("%s model: %w", name, err)
+	}
+
+	return llm, cleanup, nil
+}
+
+func kronkFactory() (fantasy.Provider, string, error) {
+	provider, err := kronk.New(
+		kronk.WithName("kronk"),
+		kronk.WithLogger(kronk.FmtLogger),
+		kronk.WithModelConfig(model.Config{
+			CacheTypeK: model.GGMLTypeQ8_0,
+			CacheTypeV: model.GGMLTypeQ8_0,
+			NBatch:     512,
+		}),
+	)
+	return provider, kronkModelURL, err
+}
+
+func openaiFactory() (fantasy.Provider, string, error) {
+	apiKey := os.Getenv("OPENAI_API_KEY")
+	if apiKey == "" {
+		return nil, "", fmt.Errorf("OPENAI_API_KEY is not set")
+	}
+	provider, err := openai.New(openai.WithAPIKey(apiKey))
+	return provider, envOr("OPENAI_MODEL", "gpt-4o"), err
+}
+
+func anthropicFactory() (fantasy.Provider, string, error) {
+	apiKey := os.Getenv("ANTHROPIC_API_KEY")
+	if apiKey == "" {
+		return nil, "", fmt.Errorf("ANTHROPIC_API_KEY is not set")
+	}
+	provider, err := anthropic.New(anthropic.WithAPIKey(apiKey))
+	return provider, envOr("ANTHROPIC_MODEL", "claude-3-5-sonnet-latest"), err
+}
+
+func googleFactory() (fantasy.Provider, string, error) {
+	apiKey := os.Getenv("GOOGLE_API_KEY")
+	if apiKey == "" {
+		return nil, "", fmt.Errorf("GOOGLE_API_KEY is not set")
+	}
+	provider, err := google.New(google.WithAPIKey(apiKey))
+	return provider, envOr("GOOGLE_MODEL", "gemini-2.0-flash"), err
+}
+
+func ollamaFactory() (fantasy.Provider, string, error) {
+	host := envOr("OLLAMA_HOST", "http://localhost:11434")
+	provider, err := ollama.New(ollama.WithHost(host))
+	return provider, envOr("OLLAMA_MODEL", "llama3.1"), err
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
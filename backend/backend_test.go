@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"charm.land/fantasy"
+)
+
+func TestNames(t *testing.T) {
+	got := Names()
+	want := []string{"anthropic", "google", "kronk", "ollama", "openai"}
+	if len(got) != len(want) {
+		t.Fatalf("Names() = %v, want %v", got, want)
+	}
+	for i, name := range want {
+		if got[i] != name {
+			t.Fatalf("Names() = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestBuildUnknownBackend(t *testing.T) {
+	_, cleanup, err := Build(context.Background(), "made-up")
+	defer cleanup()
+	if err == nil {
+		t.Fatal("Build with an unregistered backend: expected an error")
+	}
+	if !strings.Contains(err.Error(), "made-up") {
+		t.Errorf("error %q should name the unknown backend", err)
+	}
+}
+
+// withFakeFactory registers a fake backend factory for the duration of a
+// test, so Build's error-handling paths can be exercised without talking to
+// a real provider.
+func withFakeFactory(t *testing.T, name string, factory Factory) {
+	t.Helper()
+	factories[name] = factory
+	t.Cleanup(func() { delete(factories, name) })
+}
+
+func TestBuildFactoryError(t *testing.T) {
+	withFakeFactory(t, "fake", func() (fantasy.Provider, string, error) {
+		return nil, "", errors.New("missing API key")
+	})
+
+	_, cleanup, err := Build(context.Background(), "fake")
+	defer cleanup()
+	if err == nil {
+		t.Fatal("Build with a failing factory: expected an error")
+	}
+	if !strings.Contains(err.Error(), "fake provider") || !strings.Contains(err.Error(), "missing API key") {
+		t.Errorf("Build error = %q, want it to wrap the factory error with the backend name", err)
+	}
+}
+
+func TestEnvOr(t *testing.T) {
+	t.Setenv("DND_TEST_VAR", "")
+	if got := envOr("DND_TEST_VAR", "fallback"); got != "fallback" {
+		t.Errorf("envOr with unset var = %q, want %q", got, "fallback")
+	}
+
+	t.Setenv("DND_TEST_VAR", "set-value")
+	if got := envOr("DND_TEST_VAR", "fallback"); got != "set-value" {
+		t.Errorf("envOr with set var = %q, want %q", got, "set-value")
+	}
+}
@@ -0,0 +1,79 @@
+// Package campaign persists a running D&D session to disk so a multi-session
+// dungeon crawl can be resumed instead of lost on exit.
+package campaign
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"charm.land/fantasy"
+	"github.com/what-the-func/dnd-agent/combat"
+)
+
+// PlayerSheet holds the player's authoritative character stats. The DM
+// narrates around these, but they are never derived from the chat history.
+type PlayerSheet struct {
+	Name       string      `json:"name"`
+	Class      string      `json:"class"`
+	Level      int         `json:"level"`
+	HP         int         `json:"hp"`
+	MaxHP      int         `json:"max_hp"`
+	AC         int         `json:"ac"`
+	SpellSlots map[int]int `json:"spell_slots"`
+	Inventory  []string    `json:"inventory"`
+}
+
+// State is the full persisted campaign: the player's sheet, where they
+// stand, what they're working toward, the conversation so far, and any
+// combat currently in progress.
+type State struct {
+	Player    PlayerSheet       `json:"player"`
+	Scene     string            `json:"scene"`
+	QuestLog  []string          `json:"quest_log"`
+	History   []fantasy.Message `json:"history"`
+	Encounter *combat.Encounter `json:"encounter,omitempty"`
+}
+
+// NewState returns the starting state for a fresh campaign.
+func NewState() *State {
+	return &State{
+		Player: PlayerSheet{
+			Name:       "The Wizard",
+			Class:      "Wizard",
+			Level:      5,
+			HP:         32,
+			MaxHP:      32,
+			AC:         12,
+			SpellSlots: map[int]int{1: 4, 2: 3, 3: 2},
+			Inventory:  []string{"spellbook", "component pouch", "dagger"},
+		},
+		Scene: "a dungeon entrance",
+	}
+}
+
+// SaveCampaign writes state to path as indented JSON, overwriting any
+// existing file.
+func SaveCampaign(path string, state *State) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal campaign: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write campaign: %w", err)
+	}
+	return nil
+}
+
+// LoadCampaign reads a campaign previously written by SaveCampaign.
+func LoadCampaign(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read campaign: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("unmarshal campaign: %w", err)
+	}
+	return &state, nil
+}
@@ -0,0 +1,147 @@
+// Package combat models an active D&D encounter so the game keeps
+// authoritative HP, initiative order, and conditions instead of asking the
+// model to track them across turns.
+package combat
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// Combatant is one participant in an Encounter: the player or a monster.
+type Combatant struct {
+	Name       string   `json:"name"`
+	HP         int      `json:"hp"`
+	MaxHP      int      `json:"max_hp"`
+	AC         int      `json:"ac"`
+	Init       int      `json:"init"`
+	IsPC       bool     `json:"is_pc"`
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+// Encounter tracks an active combat: who's in it, whose turn it is, and
+// which round it is.
+type Encounter struct {
+	Participants []*Combatant `json:"participants"`
+	Round        int          `json:"round"`
+	Turn         int          `json:"turn"`
+}
+
+// NewEncounter returns an empty encounter starting at round 1.
+func NewEncounter() *Encounter {
+	return &Encounter{Round: 1}
+}
+
+// Add adds a combatant to the encounter.
+func (e *Encounter) Add(c *Combatant) {
+	e.Participants = append(e.Participants, c)
+}
+
+// Find returns the combatant with the given name, case-insensitive.
+func (e *Encounter) Find(name string) (*Combatant, error) {
+	for _, c := range e.Participants {
+		if strings.EqualFold(c.Name, name) {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no combatant named %q", name)
+}
+
+// PC returns the player's combatant, i.e. the one added with IsPC set.
+func (e *Encounter) PC() (*Combatant, error) {
+	for _, c := range e.Participants {
+		if c.IsPC {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("no player combatant in this encounter")
+}
+
+// RollD20 rolls a single d20.
+func RollD20() int {
+	n, _ := rand.Int(rand.Reader, big.NewInt(20))
+	return int(n.Int64()) + 1
+}
+
+// RollInitiative rolls a d20 for every combatant and sorts them into turn
+// order, highest first.
+func (e *Encounter) RollInitiative() {
+	for _, c := range e.Participants {
+		c.Init = RollD20()
+	}
+	sort.SliceStable(e.Participants, func(i, j int) bool {
+		return e.Participants[i].Init > e.Participants[j].Init
+	})
+	e.Turn = 0
+}
+
+// Current returns the combatant whose turn it currently is.
+func (e *Encounter) Current() (*Combatant, error) {
+	if len(e.Participants) == 0 {
+		return nil, fmt.Errorf("no combatants in this encounter")
+	}
+	return e.Participants[e.Turn%len(e.Participants)], nil
+}
+
+// ApplyDamage subtracts amount from target's HP, clamped at 0.
+func (e *Encounter) ApplyDamage(target string, amount int) (*Combatant, error) {
+	c, err := e.Find(target)
+	if err != nil {
+		return nil, err
+	}
+	c.HP -= amount
+	if c.HP < 0 {
+		c.HP = 0
+	}
+	return c, nil
+}
+
+// ApplyCondition tags target with a condition. A duration of 0 rounds means
+// the condition lasts until something removes it.
+func (e *Encounter) ApplyCondition(target, condition string, duration int) (*Combatant, error) {
+	c, err := e.Find(target)
+	if err != nil {
+		return nil, err
+	}
+	label := condition
+	if duration > 0 {
+		label = fmt.Sprintf("%s (%d rounds)", condition, duration)
+	}
+	c.Conditions = append(c.Conditions, label)
+	return c, nil
+}
+
+// EndTurn advances to the next combatant, incrementing Round when it wraps
+// back to the top of the order.
+func (e *Encounter) EndTurn() (*Combatant, error) {
+	if len(e.Participants) == 0 {
+		return nil, fmt.Errorf("no combatants in this encounter")
+	}
+	e.Turn++
+	if e.Turn >= len(e.Participants) {
+		e.Turn = 0
+		e.Round++
+	}
+	return e.Current()
+}
+
+// Status renders a formatted status block for the whole encounter.
+func (e *Encounter) Status() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Round %d\n", e.Round)
+	for i, c := range e.Participants {
+		marker := "  "
+		if i == e.Turn {
+			marker = "->"
+		}
+		conds := ""
+		if len(c.Conditions) > 0 {
+			conds = " [" + strings.Join(c.Conditions, ", ") + "]"
+		}
+		fmt.Fprintf(&b, "%s %s | HP %d/%d | AC %d | Init %d%s\n", marker, c.Name, c.HP, c.MaxHP, c.AC, c.Init, conds)
+	}
+	return b.String()
+}
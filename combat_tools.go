@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"charm.land/fantasy"
+	"github.com/what-the-func/dnd-agent/campaign"
+	"github.com/what-the-func/dnd-agent/combat"
+	"github.com/what-the-func/dnd-agent/dice"
+	"github.com/what-the-func/dnd-agent/srd"
+)
+
+// ---------------------------------------------------------------------------
+// Tool: start_combat
+// ---------------------------------------------------------------------------
+
+type startCombatInput struct {
+	Monsters []struct {
+		Name  string `json:"name" description:"Monster name to look up, e.g. goblin"`
+		Count int    `json:"count" description:"How many of this monster to add. Default 1."`
+	} `json:"monsters" description:"Monsters to roll into combatants and add to the encounter"`
+}
+
+func startCombatTool(state *campaign.State, client *srd.Client) fantasy.AgentTool {
+	return fantasy.NewAgentTool("start_combat",
+		"Start a combat encounter with the given monsters. Looks up each monster's "+
+			"real stats and rolls HP from its hit dice. Call this once, before any "+
+			"combat begins, then call roll_initiative.",
+		func(ctx context.Context, input startCombatInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return startCombat(ctx, input, tc, state, client)
+		},
+	)
+}
+
+func startCombat(ctx context.Context, input startCombatInput, _ fantasy.ToolCall, state *campaign.State, client *srd.Client) (fantasy.ToolResponse, error) {
+	enc := combat.NewEncounter()
+	enc.Add(&combat.Combatant{
+		Name:  state.Player.Name,
+		HP:    state.Player.HP,
+		MaxHP: state.Player.MaxHP,
+		AC:    state.Player.AC,
+		IsPC:  true,
+	})
+
+	for _, spec := range input.Monsters {
+		count := max(spec.Count, 1)
+
+		m, err := client.Monster(ctx, spec.Name)
+		if err != nil {
+			return fantasy.NewTextResponse(fmt.Sprintf("Couldn't add %s: %v", spec.Name, err)), nil
+		}
+
+		for i := range count {
+			enc.Add(combatantFromMonsterData(m, i, count))
+		}
+	}
+
+	state.Encounter = enc
+	return fantasy.NewTextResponse("Combat started.\n" + enc.Status()), nil
+}
+
+// combatantFromMonsterData builds a Combatant from SRD monster data,
+// rolling HP from hit_dice (falling back to the flat hit_points if the
+// dice notation can't be parsed). index/count number duplicate monsters,
+// e.g. "Goblin 1", "Goblin 2".
+func combatantFromMonsterData(m map[string]any, index, count int) *combat.Combatant {
+	name := fmt.Sprintf("%v", m["name"])
+	if count > 1 {
+		name = fmt.Sprintf("%s %d", name, index+1)
+	}
+
+	hp := rollHitDice(fmt.Sprintf("%v", m["hit_dice"]))
+	if hp <= 0 {
+		hp = int(toFloat(m["hit_points"]))
+	}
+
+	return &combat.Combatant{
+		Name:  name,
+		HP:    hp,
+		MaxHP: hp,
+		AC:    acValue(m["armor_class"]),
+	}
+}
+
+// rollHitDice rolls hit dice notation like "2d6" or "7d10+21" and returns
+// the total, or 0 if it can't be parsed.
+func rollHitDice(notation string) int {
+	result, err := dice.Roll(notation)
+	if err != nil {
+		return 0
+	}
+	return result.Total
+}
+
+func acValue(ac any) int {
+	if arr, ok := ac.([]any); ok && len(arr) > 0 {
+		if m, ok := arr[0].(map[string]any); ok {
+			return int(toFloat(m["value"]))
+		}
+	}
+	return 10
+}
+
+func toFloat(v any) float64 {
+	f, _ := v.(float64)
+	return f
+}
+
+// ---------------------------------------------------------------------------
+// Tool: roll_initiative
+// ---------------------------------------------------------------------------
+
+type rollInitiativeInput struct{}
+
+func rollInitiativeTool(state *campaign.State) fantasy.AgentTool {
+	return fantasy.NewAgentTool("roll_initiative",
+		"Roll initiative for every combatant in the active encounter and sort "+
+			"them into turn order. Call this once, right after start_combat.",
+		func(_ context.Context, input rollInitiativeInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return rollInitiative(input, tc, state)
+		},
+	)
+}
+
+func rollInitiative(_ rollInitiativeInput, _ fantasy.ToolCall, state *campaign.State) (fantasy.ToolResponse, error) {
+	if state.Encounter == nil {
+		return fantasy.NewTextResponse("No active encounter. Call start_combat first."), nil
+	}
+	state.Encounter.RollInitiative()
+	return fantasy.NewTextResponse("Initiative rolled.\n" + state.Encounter.Status()), nil
+}
+
+// ---------------------------------------------------------------------------
+// Tool: apply_damage
+// ---------------------------------------------------------------------------
+
+type applyDamageInput struct {
+	Target string `json:"target" description:"Name of the combatant taking damage"`
+	Amount int    `json:"amount" description:"Amount of damage to apply"`
+	Type   string `json:"type" description:"Damage type, e.g. fire, slashing (for narration only)"`
+}
+
+func applyDamageTool(state *campaign.State) fantasy.AgentTool {
+	return fantasy.NewAgentTool("apply_damage",
+		"Apply damage to a combatant in the active encounter and report their "+
+			"remaining HP. Always call this instead of tracking HP yourself.",
+		func(_ context.Context, input applyDamageInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return applyDamage(input, tc, state)
+		},
+	)
+}
+
+func applyDamage(input applyDamageInput, _ fantasy.ToolCall, state *campaign.State) (fantasy.ToolResponse, error) {
+	if state.Encounter == nil {
+		return fantasy.NewTextResponse("No active encounter."), nil
+	}
+
+	c, err := state.Encounter.ApplyDamage(input.Target, input.Amount)
+	if err != nil {
+		return fantasy.NewTextResponse(err.Error()), nil
+	}
+
+	status := fmt.Sprintf("%s takes %d %s damage (HP %d/%d)", c.Name, input.Amount, input.Type, c.HP, c.MaxHP)
+	if c.HP == 0 {
+		status += " — down!"
+	}
+	return fantasy.NewTextResponse(status), nil
+}
+
+// ---------------------------------------------------------------------------
+// Tool: apply_condition
+// ---------------------------------------------------------------------------
+
+type applyConditionInput struct {
+	Target    string `json:"target" description:"Name of the combatant to affect"`
+	Condition string `json:"condition" description:"Condition to apply, e.g. poisoned, prone, stunned"`
+	Duration  int    `json:"duration" description:"Duration in rounds, or 0 if it lasts until removed"`
+}
+
+func applyConditionTool(state *campaign.State) fantasy.AgentTool {
+	return fantasy.NewAgentTool("apply_condition",
+		"Apply a status condition to a combatant in the active encounter.",
+		func(_ context.Context, input applyConditionInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return applyCondition(input, tc, state)
+		},
+	)
+}
+
+func applyCondition(input applyConditionInput, _ fantasy.ToolCall, state *campaign.State) (fantasy.ToolResponse, error) {
+	if state.Encounter == nil {
+		return fantasy.NewTextResponse("No active encounter."), nil
+	}
+
+	c, err := state.Encounter.ApplyCondition(input.Target, input.Condition, input.Duration)
+	if err != nil {
+		return fantasy.NewTextResponse(err.Error()), nil
+	}
+
+	return fantasy.NewTextResponse(fmt.Sprintf("%s is now %s.", c.Name, input.Condition)), nil
+}
+
+// ---------------------------------------------------------------------------
+// Tool: end_turn
+// ---------------------------------------------------------------------------
+
+type endTurnInput struct{}
+
+func endTurnTool(state *campaign.State) fantasy.AgentTool {
+	return fantasy.NewAgentTool("end_turn",
+		"End the current combatant's turn and advance to the next one in "+
+			"initiative order.",
+		func(_ context.Context, input endTurnInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return endTurn(input, tc, state)
+		},
+	)
+}
+
+func endTurn(_ endTurnInput, _ fantasy.ToolCall, state *campaign.State) (fantasy.ToolResponse, error) {
+	if state.Encounter == nil {
+		return fantasy.NewTextResponse("No active encounter."), nil
+	}
+
+	next, err := state.Encounter.EndTurn()
+	if err != nil {
+		return fantasy.NewTextResponse(err.Error()), nil
+	}
+
+	return fantasy.NewTextResponse(fmt.Sprintf("It's now %s's turn.\n%s", next.Name, state.Encounter.Status())), nil
+}
+
+// ---------------------------------------------------------------------------
+// Tool: end_combat
+// ---------------------------------------------------------------------------
+
+type endCombatInput struct{}
+
+func endCombatTool(state *campaign.State) fantasy.AgentTool {
+	return fantasy.NewAgentTool("end_combat",
+		"End the active combat encounter, e.g. once every monster is defeated "+
+			"or the fight is fled.",
+		func(_ context.Context, input endCombatInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return endCombat(input, tc, state)
+		},
+	)
+}
+
+func endCombat(_ endCombatInput, _ fantasy.ToolCall, state *campaign.State) (fantasy.ToolResponse, error) {
+	if state.Encounter == nil {
+		return fantasy.NewTextResponse("No active encounter."), nil
+	}
+
+	if pc, err := state.Encounter.PC(); err == nil {
+		state.Player.HP = pc.HP
+	}
+
+	state.Encounter = nil
+	return fantasy.NewTextResponse("Combat has ended."), nil
+}
@@ -0,0 +1,104 @@
+package dice
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokPlus
+	tokMinus
+	tokD
+	tokKH
+	tokKL
+	tokBang
+	tokAdv
+	tokDis
+)
+
+type token struct {
+	kind tokenKind
+	num  int
+}
+
+// lexer turns dice notation into a stream of tokens. It's hand-written
+// rather than built on regexp so the parser can produce specific error
+// messages ("expected a number after 'd'") instead of a match/no-match.
+type lexer struct {
+	input []rune
+	pos   int
+}
+
+func newLexer(s string) *lexer {
+	return &lexer{input: []rune(s)}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipSpace()
+	if l.pos >= len(l.input) {
+		return token{kind: tokEOF}, nil
+	}
+
+	c := l.input[l.pos]
+	switch {
+	case c == '+':
+		l.pos++
+		return token{kind: tokPlus}, nil
+	case c == '-':
+		l.pos++
+		return token{kind: tokMinus}, nil
+	case c == '!':
+		l.pos++
+		return token{kind: tokBang}, nil
+	case c >= '0' && c <= '9':
+		start := l.pos
+		for l.pos < len(l.input) && l.input[l.pos] >= '0' && l.input[l.pos] <= '9' {
+			l.pos++
+		}
+		n, err := strconv.Atoi(string(l.input[start:l.pos]))
+		if err != nil {
+			return token{}, fmt.Errorf("invalid number %q", string(l.input[start:l.pos]))
+		}
+		return token{kind: tokNumber, num: n}, nil
+	case isLetter(c):
+		start := l.pos
+		for l.pos < len(l.input) && isLetter(l.input[l.pos]) {
+			l.pos++
+		}
+		return wordToken(strings.ToLower(string(l.input[start:l.pos])))
+	default:
+		return token{}, fmt.Errorf("unexpected character %q", string(c))
+	}
+}
+
+func wordToken(word string) (token, error) {
+	switch word {
+	case "d":
+		return token{kind: tokD}, nil
+	case "kh":
+		return token{kind: tokKH}, nil
+	case "kl":
+		return token{kind: tokKL}, nil
+	case "adv", "advantage":
+		return token{kind: tokAdv}, nil
+	case "dis", "disadvantage":
+		return token{kind: tokDis}, nil
+	default:
+		return token{}, fmt.Errorf("unexpected word %q", word)
+	}
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.input) && (l.input[l.pos] == ' ' || l.input[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+func isLetter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
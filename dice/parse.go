@@ -0,0 +1,187 @@
+package dice
+
+import "fmt"
+
+// DieTerm is a single dice group within an expression, e.g. the "4d6kh3" in
+// "4d6kh3+2".
+type DieTerm struct {
+	Count        int
+	Sides        int
+	KeepCount    int  // 0 means keep all
+	KeepHigh     bool // true for kh, false for kl
+	Advantage    bool // roll twice, keep the higher
+	Disadvantage bool // roll twice, keep the lower
+	Exploding    bool // reroll and add again on max
+}
+
+// Term is one signed piece of an expression: either a DieTerm or a flat
+// constant, as in the "+4" of "1d8+1d6+4".
+type Term struct {
+	Sign  int // +1 or -1
+	Die   *DieTerm
+	Const int
+}
+
+// Expression is a fully parsed dice notation string, ready to Roll.
+type Expression struct {
+	Terms []Term
+}
+
+// Parse parses D&D dice notation: 2d6+3, 1d20+5 adv/dis, 4d6kh3, 2d6!, and
+// multi-term sums like 1d8+1d6+4.
+func Parse(notation string) (*Expression, error) {
+	p := &parser{lex: newLexer(notation)}
+	if err := p.advance(); err != nil {
+		return nil, fmt.Errorf("dice: %w", err)
+	}
+
+	expr := &Expression{}
+	sign := 1
+	for {
+		term, err := p.parseTerm(sign)
+		if err != nil {
+			return nil, fmt.Errorf("dice: %w", err)
+		}
+		expr.Terms = append(expr.Terms, term)
+
+		switch p.tok.kind {
+		case tokPlus:
+			sign = 1
+		case tokMinus:
+			sign = -1
+		default:
+			// A trailing adv/dis modifies the last die term, e.g.
+			// "1d20+5 adv" means advantage on the d20, not the +5.
+			for p.tok.kind == tokAdv || p.tok.kind == tokDis {
+				if err := applyAdvantage(expr, p.tok.kind == tokAdv); err != nil {
+					return nil, fmt.Errorf("dice: %w", err)
+				}
+				if err := p.advance(); err != nil {
+					return nil, fmt.Errorf("dice: %w", err)
+				}
+			}
+			if p.tok.kind != tokEOF {
+				return nil, fmt.Errorf("dice: unexpected trailing input in %q", notation)
+			}
+			return expr, nil
+		}
+		if err := p.advance(); err != nil {
+			return nil, fmt.Errorf("dice: %w", err)
+		}
+	}
+}
+
+// applyAdvantage attaches adv/dis to the last (rightmost) die term, since
+// notation like "1d20+5 adv" means advantage on the d20, not the modifier.
+func applyAdvantage(expr *Expression, adv bool) error {
+	for i := len(expr.Terms) - 1; i >= 0; i-- {
+		if die := expr.Terms[i].Die; die != nil {
+			if adv {
+				die.Advantage = true
+			} else {
+				die.Disadvantage = true
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("adv/dis with no dice term to apply it to")
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	t, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = t
+	return nil
+}
+
+func (p *parser) parseTerm(sign int) (Term, error) {
+	switch p.tok.kind {
+	case tokNumber:
+		n := p.tok.num
+		if err := p.advance(); err != nil {
+			return Term{}, err
+		}
+		if p.tok.kind == tokD {
+			return p.parseDiceTerm(sign, n)
+		}
+		return Term{Sign: sign, Const: n}, nil
+	case tokD:
+		return p.parseDiceTerm(sign, 1)
+	default:
+		return Term{}, fmt.Errorf("expected a number or dice term")
+	}
+}
+
+// parseDiceTerm parses the "d20kh3!" tail of a dice term; p.tok is tokD and
+// count has already been read (or defaulted to 1 for bare "d20").
+func (p *parser) parseDiceTerm(sign, count int) (Term, error) {
+	if err := p.advance(); err != nil {
+		return Term{}, err
+	}
+	if p.tok.kind != tokNumber {
+		return Term{}, fmt.Errorf("expected number of sides after 'd'")
+	}
+	sides := p.tok.num
+	if sides < 1 {
+		return Term{}, fmt.Errorf("die sides must be at least 1, got d%d", sides)
+	}
+	if err := p.advance(); err != nil {
+		return Term{}, err
+	}
+
+	die := &DieTerm{Count: count, Sides: sides}
+
+	for {
+		switch p.tok.kind {
+		case tokBang:
+			if sides == 1 {
+				return Term{}, fmt.Errorf("d1 can't explode, it always rolls the max")
+			}
+			die.Exploding = true
+			if err := p.advance(); err != nil {
+				return Term{}, err
+			}
+		case tokKH, tokKL:
+			keepHigh := p.tok.kind == tokKH
+			if err := p.advance(); err != nil {
+				return Term{}, err
+			}
+			if p.tok.kind != tokNumber {
+				return Term{}, fmt.Errorf("expected number of dice to keep after kh/kl")
+			}
+			die.KeepCount = p.tok.num
+			die.KeepHigh = keepHigh
+			if err := p.advance(); err != nil {
+				return Term{}, err
+			}
+		default:
+			return Term{Sign: sign, Die: die}, nil
+		}
+	}
+}
+
+// String renders the die term back to notation for display, e.g. "4d6kh3".
+func (d *DieTerm) String() string {
+	s := fmt.Sprintf("%dd%d", d.Count, d.Sides)
+	switch {
+	case d.Advantage:
+		s += " adv"
+	case d.Disadvantage:
+		s += " dis"
+	case d.KeepCount > 0 && d.KeepHigh:
+		s += fmt.Sprintf("kh%d", d.KeepCount)
+	case d.KeepCount > 0:
+		s += fmt.Sprintf("kl%d", d.KeepCount)
+	}
+	if d.Exploding {
+		s += "!"
+	}
+	return s
+}
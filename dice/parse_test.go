@@ -0,0 +1,122 @@
+package dice
+
+import "testing"
+
+func TestParseBasic(t *testing.T) {
+	cases := []struct {
+		notation string
+		want     Expression
+	}{
+		{"2d6+3", Expression{Terms: []Term{
+			{Sign: 1, Die: &DieTerm{Count: 2, Sides: 6}},
+			{Sign: 1, Const: 3},
+		}}},
+		{"1d20+5", Expression{Terms: []Term{
+			{Sign: 1, Die: &DieTerm{Count: 1, Sides: 20}},
+			{Sign: 1, Const: 5},
+		}}},
+		{"1d8+1d6+4", Expression{Terms: []Term{
+			{Sign: 1, Die: &DieTerm{Count: 1, Sides: 8}},
+			{Sign: 1, Die: &DieTerm{Count: 1, Sides: 6}},
+			{Sign: 1, Const: 4},
+		}}},
+		{"4d6-2", Expression{Terms: []Term{
+			{Sign: 1, Die: &DieTerm{Count: 4, Sides: 6}},
+			{Sign: -1, Const: 2},
+		}}},
+	}
+
+	for _, c := range cases {
+		got, err := Parse(c.notation)
+		if err != nil {
+			t.Fatalf("Parse(%q): unexpected error: %v", c.notation, err)
+		}
+		if len(got.Terms) != len(c.want.Terms) {
+			t.Fatalf("Parse(%q): got %d terms, want %d", c.notation, len(got.Terms), len(c.want.Terms))
+		}
+		for i, term := range got.Terms {
+			wantTerm := c.want.Terms[i]
+			if term.Sign != wantTerm.Sign || term.Const != wantTerm.Const {
+				t.Errorf("Parse(%q) term %d: got %+v, want %+v", c.notation, i, term, wantTerm)
+			}
+			if (term.Die == nil) != (wantTerm.Die == nil) {
+				t.Errorf("Parse(%q) term %d: die presence mismatch", c.notation, i)
+				continue
+			}
+			if term.Die != nil && (term.Die.Count != wantTerm.Die.Count || term.Die.Sides != wantTerm.Die.Sides) {
+				t.Errorf("Parse(%q) term %d die: got %+v, want %+v", c.notation, i, term.Die, wantTerm.Die)
+			}
+		}
+	}
+}
+
+func TestParseAdvantageDisadvantage(t *testing.T) {
+	expr, err := Parse("1d20+5 adv")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	die := expr.Terms[0].Die
+	if die == nil || !die.Advantage {
+		t.Fatalf("expected advantage on the d20 term, got %+v", expr.Terms[0])
+	}
+
+	expr, err = Parse("1d20 dis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	die = expr.Terms[0].Die
+	if die == nil || !die.Disadvantage {
+		t.Fatalf("expected disadvantage on the d20 term, got %+v", expr.Terms[0])
+	}
+}
+
+func TestParseKeepHighLow(t *testing.T) {
+	expr, err := Parse("4d6kh3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	die := expr.Terms[0].Die
+	if die == nil || die.KeepCount != 3 || !die.KeepHigh {
+		t.Fatalf("expected kh3, got %+v", die)
+	}
+
+	expr, err = Parse("2d20kl1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	die = expr.Terms[0].Die
+	if die == nil || die.KeepCount != 1 || die.KeepHigh {
+		t.Fatalf("expected kl1, got %+v", die)
+	}
+}
+
+func TestParseExploding(t *testing.T) {
+	expr, err := Parse("2d6!")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	die := expr.Terms[0].Die
+	if die == nil || !die.Exploding {
+		t.Fatalf("expected exploding die, got %+v", die)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"",
+		"d",
+		"2d",
+		"2d6kh",
+		"5 adv",
+		"2d6 foo",
+		"2d6+",
+		"1d0",
+		"2d-3",
+		"1d1!",
+	}
+	for _, notation := range cases {
+		if _, err := Parse(notation); err == nil {
+			t.Errorf("Parse(%q): expected an error, got none", notation)
+		}
+	}
+}
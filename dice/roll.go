@@ -0,0 +1,157 @@
+package dice
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// DieResult is one rolled die, with whether it counted toward the total
+// (dropped by kh/kl or a discarded adv/dis roll are kept=false).
+type DieResult struct {
+	Value int
+	Kept  bool
+}
+
+// TermResult is one evaluated term: either the rolls for a DieTerm, or a
+// flat constant.
+type TermResult struct {
+	Sign     int
+	Notation string
+	Const    int
+	Dice     []DieResult
+	Sum      int // this term's signed contribution to the total
+}
+
+// Result is a fully evaluated dice expression.
+type Result struct {
+	Notation string
+	Terms    []TermResult
+	Total    int
+}
+
+// Roll parses and evaluates dice notation in one step.
+func Roll(notation string) (*Result, error) {
+	expr, err := Parse(notation)
+	if err != nil {
+		return nil, err
+	}
+	return expr.Roll(notation), nil
+}
+
+// Roll evaluates an already-parsed expression, rolling fresh dice.
+func (e *Expression) Roll(notation string) *Result {
+	result := &Result{Notation: notation}
+	for _, term := range e.Terms {
+		tr := term.roll()
+		result.Terms = append(result.Terms, tr)
+		result.Total += tr.Sum
+	}
+	return result
+}
+
+func (t Term) roll() TermResult {
+	if t.Die == nil {
+		return TermResult{Sign: t.Sign, Const: t.Const, Sum: t.Sign * t.Const}
+	}
+
+	dice := t.Die.roll()
+	sum := 0
+	for _, d := range dice {
+		if d.Kept {
+			sum += d.Value
+		}
+	}
+
+	return TermResult{
+		Sign:     t.Sign,
+		Notation: t.Die.String(),
+		Dice:     dice,
+		Sum:      t.Sign * sum,
+	}
+}
+
+func (d *DieTerm) roll() []DieResult {
+	count := d.Count
+	if d.Advantage || d.Disadvantage {
+		count = 2
+	}
+
+	var results []DieResult
+	for range count {
+		results = append(results, rollOne(d.Sides, d.Exploding)...)
+	}
+
+	switch {
+	case d.Advantage:
+		keepBest(results, 1, true)
+	case d.Disadvantage:
+		keepBest(results, 1, false)
+	case d.KeepCount > 0:
+		keepBest(results, d.KeepCount, d.KeepHigh)
+	}
+
+	return results
+}
+
+// rollOne rolls a single die of the given size, and if exploding, keeps
+// rolling and adding extra dice for as long as it keeps coming up max.
+func rollOne(sides int, exploding bool) []DieResult {
+	results := []DieResult{{Value: rollDie(sides), Kept: true}}
+	if !exploding {
+		return results
+	}
+	for results[len(results)-1].Value == sides {
+		results = append(results, DieResult{Value: rollDie(sides), Kept: true})
+	}
+	return results
+}
+
+func rollDie(sides int) int {
+	n, _ := rand.Int(rand.Reader, big.NewInt(int64(sides)))
+	return int(n.Int64()) + 1
+}
+
+// keepBest marks all but the best (or worst) n results as dropped.
+func keepBest(results []DieResult, n int, high bool) {
+	order := make([]int, len(results))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		if high {
+			return results[order[i]].Value > results[order[j]].Value
+		}
+		return results[order[i]].Value < results[order[j]].Value
+	})
+
+	for i, idx := range order {
+		results[idx].Kept = i < n
+	}
+}
+
+// String renders a result the way the DM would narrate it, e.g.
+// "Rolling 2d6+3: [4 5]+3 = 12".
+func (r *Result) String() string {
+	s := fmt.Sprintf("Rolling %s:", r.Notation)
+	for _, term := range r.Terms {
+		if term.Dice == nil {
+			s += fmt.Sprintf(" %+d", term.Sign*term.Const)
+			continue
+		}
+		s += " ["
+		for i, d := range term.Dice {
+			if i > 0 {
+				s += " "
+			}
+			if d.Kept {
+				s += fmt.Sprintf("%d", d.Value)
+			} else {
+				s += fmt.Sprintf("(%d)", d.Value)
+			}
+		}
+		s += "]"
+	}
+	return fmt.Sprintf("%s = %d", s, r.Total)
+}
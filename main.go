@@ -3,32 +3,34 @@ package main
 import (
 	"bufio"
 	"context"
-	"crypto/rand"
-	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
-	"math/big"
-	"net/http"
 	"os"
 	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"charm.land/fantasy"
-	"charm.land/fantasy/providers/kronk"
-	"github.com/ardanlabs/kronk/sdk/kronk/model"
 	"github.com/joho/godotenv"
+	"github.com/what-the-func/dnd-agent/backend"
+	"github.com/what-the-func/dnd-agent/campaign"
+	"github.com/what-the-func/dnd-agent/dice"
+	"github.com/what-the-func/dnd-agent/render"
+	"github.com/what-the-func/dnd-agent/srd"
 )
 
-const modelURL = "Qwen/Qwen3-8B-GGUF/Qwen3-8B-Q5_K_M.gguf"
-
 const systemPrompt = `You are a D&D 5e Dungeon Master. The player is a level 5 wizard (32 HP, AC 12) with Fireball, Shield, Misty Step, and Magic Missile prepared. They stand at a dungeon entrance.
 
 Keep responses to 2-3 sentences max. Never ramble. After describing the scene, stop and use ask_player immediately.`
 
 var playerScanner = bufio.NewScanner(os.Stdin)
 
+// activeRenderer is the terminal output surface for the running game,
+// chosen in run() based on --no-color and NO_COLOR.
+var activeRenderer render.Renderer = render.Plain{}
+
 func main() {
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -36,65 +38,112 @@ func main() {
 	}
 }
 
+// defaultBackend picks the --backend flag's default: DND_BACKEND if set,
+// otherwise backend.Default.
+func defaultBackend() string {
+	if name := os.Getenv("DND_BACKEND"); name != "" {
+		return name
+	}
+	return backend.Default
+}
+
 func run() error {
+	loadPath := flag.String("load", "", "path to a saved campaign to resume")
+	savePath := flag.String("save", "", "path to save campaign progress to after each DM turn")
+	noColor := flag.Bool("no-color", false, "disable colorized output")
+	offline := flag.Bool("offline", false, "never hit the network; use cached or embedded SRD data only")
+	backendName := flag.String("backend", defaultBackend(),
+		fmt.Sprintf("model backend to use (%s)", strings.Join(backend.Names(), ", ")))
+	flag.Parse()
+
+	activeRenderer = render.New(*noColor)
+
+	srdClient, err := srd.NewClient(*offline)
+	if err != nil {
+		return fmt.Errorf("srd client: %w", err)
+	}
+
 	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 
+	// Warm the cache for common early-game monsters so the first lookups
+	// in play don't pay network latency. Best-effort: a prefetch failure
+	// just means those lookups fall back to network/embedded data later.
+	if err := srdClient.Prefetch(sigCtx, "goblin", "skeleton", "owlbear"); err != nil {
+		fmt.Printf("SRD prefetch: %v\n", err)
+	}
+
 	godotenv.Load()
 
-	provider, err := kronk.New(
-		kronk.WithName("kronk"),
-		kronk.WithLogger(kronk.FmtLogger),
-		kronk.WithModelConfig(model.Config{
-			CacheTypeK: model.GGMLTypeQ8_0,
-			CacheTypeV: model.GGMLTypeQ8_0,
-			NBatch:     512,
-		}),
-	)
-	if err != nil {
-		return fmt.Errorf("provider: %w", err)
-	}
-	defer func() {
-		if c, ok := provider.(interface{ Close(context.Context) error }); ok {
-			c.Close(context.Background())
+	state := campaign.NewState()
+	if *loadPath != "" {
+		loaded, err := campaign.LoadCampaign(*loadPath)
+		if err != nil {
+			return fmt.Errorf("load campaign: %w", err)
 		}
-	}()
+		state = loaded
+	}
 
-	llm, err := provider.LanguageModel(sigCtx, modelURL)
+	llm, cleanup, err := backend.Build(sigCtx, *backendName)
 	if err != nil {
-		return fmt.Errorf("model: %w", err)
+		return fmt.Errorf("backend: %w", err)
+	}
+	defer cleanup()
+
+	rs := &replState{
+		campaign: state,
+		history:  new([]fantasy.Message),
+		savePath: savePath,
+		quit:     new(bool),
+		retry:    new(bool),
 	}
 
 	agent := fantasy.NewAgent(llm,
 		fantasy.WithSystemPrompt(systemPrompt),
 		fantasy.WithTools(
-			playerTool(),
-			monsterTool(),
-			spellTool(),
+			playerTool(rs),
+			monsterTool(srdClient, state),
+			spellTool(srdClient),
 			diceTool(),
+			stateQueryTool(state),
+			startCombatTool(state, srdClient),
+			rollInitiativeTool(state),
+			applyDamageTool(state),
+			applyConditionTool(state),
+			endTurnTool(state),
+			endCombatTool(state),
 		),
 		fantasy.WithMaxOutputTokens(2048),
 		fantasy.WithTemperature(0.8),
 	)
 
-	return gameLoop(sigCtx, agent)
+	return gameLoop(sigCtx, agent, state, rs)
 }
 
 // ---------------------------------------------------------------------------
 // Game loop
 // ---------------------------------------------------------------------------
 
-// gameLoop runs the turn-based game indefinitely until Ctrl+C.
-// Each iteration is one DM turn. Conversation history accumulates between turns.
-func gameLoop(sigCtx context.Context, agent fantasy.Agent) error {
-	var history []fantasy.Message
+// gameLoop runs the turn-based game indefinitely until Ctrl+C or the player
+// saves and quits. Each iteration is one DM turn. Conversation history
+// accumulates between turns and is snapshotted into state after every turn.
+// rs is shared with the ask_player tool so REPL commands like /retry and
+// /history can read and mutate the same history slice the loop is driving.
+func gameLoop(sigCtx context.Context, agent fantasy.Agent, state *campaign.State, rs *replState) error {
+	history := state.History
 	prompt := "Begin."
+	if len(history) > 0 {
+		prompt = "Continue."
+	}
 
 	fmt.Println("=== D&D 5e ===")
-	fmt.Println("Press Ctrl+C to quit")
+	fmt.Println("Press Ctrl+C to quit, or answer ask_player with 'save' to save and quit, or /help for commands")
 	fmt.Println()
 
 	for {
+		*rs.history = history
+		*rs.retry = false
+
 		ctx, cancel := context.WithTimeout(sigCtx, 30*time.Minute)
 
 		result, err := agent.Stream(ctx, fantasy.AgentStreamCall{
@@ -117,10 +166,27 @@ func gameLoop(sigCtx context.Context, agent fantasy.Agent) error {
 			return fmt.Errorf("stream: %w", err)
 		}
 
+		if *rs.retry {
+			fmt.Println()
+			continue
+		}
+
 		for _, step := range result.Steps {
 			history = append(history, step.Messages...)
 		}
 
+		state.History = history
+		if *rs.savePath != "" {
+			if err := campaign.SaveCampaign(*rs.savePath, state); err != nil {
+				fmt.Printf("\nWarning: failed to autosave campaign: %v\n", err)
+			}
+		}
+
+		if *rs.quit {
+			fmt.Println("\n--- Thanks for playing! ---")
+			return nil
+		}
+
 		fmt.Println()
 		prompt = "Continue."
 	}
@@ -136,7 +202,7 @@ func onReasoningStart(_ string, _ fantasy.ReasoningContent) error {
 }
 
 func onReasoningDelta(_, text string) error {
-	fmt.Print(text)
+	activeRenderer.Reasoning(text)
 	return nil
 }
 
@@ -146,20 +212,20 @@ func onReasoningEnd(_ string, _ fantasy.ReasoningContent) error {
 }
 
 func onTextDelta(_, text string) error {
-	fmt.Print(text)
+	activeRenderer.SceneText(text)
 	return nil
 }
 
 func onToolCall(tc fantasy.ToolCallContent) error {
 	if tc.ToolName != "ask_player" {
-		fmt.Printf("\n[%s] %s\n", tc.ToolName, tc.Input)
+		activeRenderer.ToolCall(tc.ToolName, fmt.Sprintf("%s", tc.Input))
 	}
 	return nil
 }
 
 func onToolResult(res fantasy.ToolResultContent) error {
 	if res.ToolName != "ask_player" {
-		fmt.Println("-> done")
+		activeRenderer.ToolResult(res.ToolName)
 	}
 	return nil
 }
@@ -168,36 +234,46 @@ func onToolResult(res fantasy.ToolResultContent) error {
 // Tool definitions
 // ---------------------------------------------------------------------------
 
-func playerTool() fantasy.AgentTool {
+func playerTool(rs *replState) fantasy.AgentTool {
 	return fantasy.NewAgentTool("ask_player",
 		"Present the player with choices. You must call this whenever it is the "+
 			"player's turn to act. Provide a question and 3-5 options. Do not write "+
 			"options in your response text — this tool handles the display. The game "+
-			"cannot continue until the player chooses.",
-		askPlayer,
+			"cannot continue until the player chooses. The player may also type "+
+			"'save' to save the campaign and quit, or a /command (try /help).",
+		func(ctx context.Context, input askPlayerInput, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return askPlayer(ctx, input, tc, rs)
+		},
 	)
 }
 
-func monsterTool() fantasy.AgentTool {
+func monsterTool(client *srd.Client, state *campaign.State) fantasy.AgentTool {
 	return fantasy.NewAgentTool("lookup_monster",
 		"Look up a D&D 5e monster by name to get its real stats. Always call "+
-			"this before using any monster in the game.",
-		lookupMonster,
+			"this before using any monster in the game. If combat is active, "+
+			"the monster is automatically rolled into a combatant and added "+
+			"to the encounter.",
+		func(ctx context.Context, input monsterQuery, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return lookupMonster(ctx, input, tc, client, state)
+		},
 	)
 }
 
-func spellTool() fantasy.AgentTool {
+func spellTool(client *srd.Client) fantasy.AgentTool {
 	return fantasy.NewAgentTool("lookup_spell",
 		"Look up a D&D 5e spell by name to get its real details. Always call "+
 			"this before resolving a spell.",
-		lookupSpell,
+		func(ctx context.Context, input spellQuery, tc fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return lookupSpell(ctx, input, tc, client)
+		},
 	)
 }
 
 func diceTool() fantasy.AgentTool {
 	return fantasy.NewAgentTool("roll_dice",
-		"Roll dice. Specify the number of dice, sides per die, and an optional "+
-			"modifier. Always call this — never generate random numbers yourself.",
+		"Roll dice using standard notation, e.g. 2d6+3, 1d20+5 adv, 4d6kh3 "+
+			"(keep highest 3), 2d6! (exploding), or multi-term sums like "+
+			"1d8+1d6+4. Always call this — never generate random numbers yourself.",
 		rollDice,
 	)
 }
@@ -211,20 +287,44 @@ type askPlayerInput struct {
 	Options  []string `json:"options" description:"List of 3-5 options the player can choose from"`
 }
 
-func askPlayer(_ context.Context, input askPlayerInput, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
-	fmt.Printf("\n\n--- YOUR TURN ---\n%s\n\n", input.Question)
-	for i, opt := range input.Options {
-		fmt.Printf("  %d. %s\n", i+1, opt)
-	}
+func askPlayer(_ context.Context, input askPlayerInput, _ fantasy.ToolCall, rs *replState) (fantasy.ToolResponse, error) {
+	activeRenderer.PlayerPrompt(input.Question, input.Options)
 
 	for {
-		fmt.Printf("\nChoose [1-%d]: ", len(input.Options))
+		fmt.Printf("\nChoose [1-%d], or 'save' to save and quit, or /help for commands: ", len(input.Options))
 
 		if !playerScanner.Scan() {
 			return fantasy.NewTextResponse("The player has left the game."), nil
 		}
 
 		text := strings.TrimSpace(playerScanner.Text())
+
+		if strings.HasPrefix(text, "/") {
+			// REPL commands never consume the player's turn — they print
+			// local state or mutate history, then loop back for real input.
+			// /retry is the exception: it ends this turn early so gameLoop
+			// can discard it and ask the DM to retry.
+			dispatchReplCommand(rs, text)
+			if *rs.retry {
+				return fantasy.NewTextResponse("The player asked to retry this turn."), nil
+			}
+			continue
+		}
+
+		if strings.EqualFold(text, "save") {
+			*rs.quit = true
+			if *rs.savePath == "" {
+				fmt.Println("No --save path was given; progress will not be written.")
+				return fantasy.NewTextResponse("The player saved and quit."), nil
+			}
+			if err := campaign.SaveCampaign(*rs.savePath, rs.campaign); err != nil {
+				fmt.Printf("Failed to save campaign: %v\n", err)
+			} else {
+				fmt.Printf("Campaign saved to %s.\n", *rs.savePath)
+			}
+			return fantasy.NewTextResponse("The player saved and quit."), nil
+		}
+
 		choice, err := strconv.Atoi(text)
 		if err != nil || choice < 1 || choice > len(input.Options) {
 			fmt.Printf("Pick a number between 1 and %d.\n", len(input.Options))
@@ -245,23 +345,12 @@ type monsterQuery struct {
 	Name string `json:"name" description:"Monster name, e.g. owlbear, dragon, goblin"`
 }
 
-func lookupMonster(_ context.Context, input monsterQuery, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
-	slug := strings.ToLower(strings.ReplaceAll(input.Name, " ", "-"))
-
-	resp, err := http.Get("https://www.dnd5eapi.co/api/monsters/" + slug)
+func lookupMonster(ctx context.Context, input monsterQuery, _ fantasy.ToolCall, client *srd.Client, state *campaign.State) (fantasy.ToolResponse, error) {
+	m, err := client.Monster(ctx, input.Name)
 	if err != nil {
-		return fantasy.NewTextResponse("Failed to reach D&D API"), nil
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
 		return fantasy.NewTextResponse(fmt.Sprintf("Monster '%s' not found", input.Name)), nil
 	}
 
-	body, _ := io.ReadAll(resp.Body)
-	var m map[string]any
-	json.Unmarshal(body, &m)
-
 	summary := fmt.Sprintf(
 		"%s (%s %s, CR %v) | AC %v | HP %v (%v)\n"+
 			"STR %v DEX %v CON %v INT %v WIS %v CHA %v | Speed: %v",
@@ -288,6 +377,15 @@ func lookupMonster(_ context.Context, input monsterQuery, _ fantasy.ToolCall) (f
 		}
 	}
 
+	if state.Encounter != nil {
+		name := fmt.Sprintf("%v", m["name"])
+		if _, err := state.Encounter.Find(name); err != nil {
+			c := combatantFromMonsterData(m, 0, 1)
+			state.Encounter.Add(c)
+			summary += fmt.Sprintf("\n\n%s joins the encounter with %d/%d HP.", c.Name, c.HP, c.MaxHP)
+		}
+	}
+
 	return fantasy.NewTextResponse(summary), nil
 }
 
@@ -299,23 +397,12 @@ type spellQuery struct {
 	Name string `json:"name" description:"Spell name, e.g. fireball, magic-missile, shield"`
 }
 
-func lookupSpell(_ context.Context, input spellQuery, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
-	slug := strings.ToLower(strings.ReplaceAll(input.Name, " ", "-"))
-
-	resp, err := http.Get("https://www.dnd5eapi.co/api/spells/" + slug)
+func lookupSpell(ctx context.Context, input spellQuery, _ fantasy.ToolCall, client *srd.Client) (fantasy.ToolResponse, error) {
+	s, err := client.Spell(ctx, input.Name)
 	if err != nil {
-		return fantasy.NewTextResponse("Failed to reach D&D API"), nil
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
 		return fantasy.NewTextResponse(fmt.Sprintf("Spell '%s' not found", input.Name)), nil
 	}
 
-	body, _ := io.ReadAll(resp.Body)
-	var s map[string]any
-	json.Unmarshal(body, &s)
-
 	desc := ""
 	if descs, ok := s["desc"].([]any); ok && len(descs) > 0 {
 		desc = fmt.Sprintf("%v", descs[0])
@@ -331,8 +418,8 @@ func lookupSpell(_ context.Context, input spellQuery, _ fantasy.ToolCall) (fanta
 	if dmg, ok := s["damage"].(map[string]any); ok {
 		if atSlot, ok := dmg["damage_at_slot_level"].(map[string]any); ok {
 			summary += "\nDamage by slot:"
-			for lvl, dice := range atSlot {
-				summary += fmt.Sprintf(" L%s=%v", lvl, dice)
+			for lvl, notation := range atSlot {
+				summary += fmt.Sprintf(" L%s=%v", lvl, notation)
 			}
 		}
 	}
@@ -345,30 +432,78 @@ func lookupSpell(_ context.Context, input spellQuery, _ fantasy.ToolCall) (fanta
 // ---------------------------------------------------------------------------
 
 type diceQuery struct {
-	Count    int `json:"count" description:"Number of dice to roll (e.g. 2 for 2d6)"`
-	Sides    int `json:"sides" description:"Sides per die (e.g. 20 for d20)"`
-	Modifier int `json:"modifier" description:"Added to total (e.g. 5 for +5, -2 for penalty). Default 0."`
+	Notation string `json:"notation" description:"Dice notation, e.g. 2d6+3, 1d20+5 adv, 4d6kh3, 2d6!, 1d8+1d6+4"`
 }
 
 func rollDice(_ context.Context, input diceQuery, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
-	count := max(input.Count, 1)
-	sides := max(input.Sides, 1)
-
-	rolls := make([]int, count)
-	total := 0
-	for i := range count {
-		n, _ := rand.Int(rand.Reader, big.NewInt(int64(sides)))
-		rolls[i] = int(n.Int64()) + 1
-		total += rolls[i]
+	result, err := dice.Roll(input.Notation)
+	if err != nil {
+		return fantasy.NewTextResponse(fmt.Sprintf("Couldn't parse %q: %v", input.Notation, err)), nil
+	}
+
+	activeRenderer.DiceRoll(input.Notation, flattenRolls(result), result.Total)
+	return fantasy.NewTextResponse(result.String()), nil
+}
+
+// flattenRolls collects every kept die value across a result's terms, for
+// renderers that just want the raw numbers rolled.
+func flattenRolls(result *dice.Result) []int {
+	var rolls []int
+	for _, term := range result.Terms {
+		for _, d := range term.Dice {
+			if d.Kept {
+				rolls = append(rolls, d.Value)
+			}
+		}
+	}
+	return rolls
+}
+
+// ---------------------------------------------------------------------------
+// Tool: state_query
+// ---------------------------------------------------------------------------
+
+type stateQueryInput struct{}
+
+func stateQueryTool(state *campaign.State) fantasy.AgentTool {
+	return fantasy.NewAgentTool("state_query",
+		"Read the player's authoritative character sheet (HP, AC, spell slots, "+
+			"inventory), quest log, and any active combat. Call this instead of "+
+			"guessing these values from the conversation history.",
+		func(_ context.Context, _ stateQueryInput, _ fantasy.ToolCall) (fantasy.ToolResponse, error) {
+			return fantasy.NewTextResponse(formatPlayerState(state)), nil
+		},
+	)
+}
+
+func formatPlayerState(state *campaign.State) string {
+	p := state.Player
+	levels := make([]int, 0, len(p.SpellSlots))
+	for lvl := range p.SpellSlots {
+		levels = append(levels, lvl)
+	}
+	sort.Ints(levels)
+
+	slots := make([]string, 0, len(levels))
+	for _, lvl := range levels {
+		slots = append(slots, fmt.Sprintf("L%d=%d", lvl, p.SpellSlots[lvl]))
+	}
+
+	summary := fmt.Sprintf(
+		"%s (Level %d %s) | HP %d/%d | AC %d\nSpell slots: %s\nInventory: %s\nScene: %s",
+		p.Name, p.Level, p.Class, p.HP, p.MaxHP, p.AC,
+		strings.Join(slots, " "), strings.Join(p.Inventory, ", "), state.Scene,
+	)
+
+	if len(state.QuestLog) > 0 {
+		summary += "\nQuest log:\n- " + strings.Join(state.QuestLog, "\n- ")
 	}
-	total += input.Modifier
 
-	notation := fmt.Sprintf("%dd%d", count, sides)
-	if input.Modifier != 0 {
-		notation += fmt.Sprintf("%+d", input.Modifier)
+	if state.Encounter != nil {
+		summary += "\nActive combat:\n" + state.Encounter.Status()
 	}
 
-	return fantasy.NewTextResponse(fmt.Sprintf("Rolling %s: %v = %d", notation, rolls, total)), nil
+	return summary
 }
 
 // ---------------------------------------------------------------------------
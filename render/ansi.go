@@ -0,0 +1,55 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+)
+
+// ANSI renders with color-tagged output: DM narration, [THINKING], tool
+// I/O, and dice rolls each get a distinct color.
+type ANSI struct {
+	scene  *color.Color
+	think  *color.Color
+	tool   *color.Color
+	dice   *color.Color
+	prompt *color.Color
+}
+
+// NewANSI returns an ANSI renderer with the default color scheme.
+func NewANSI() *ANSI {
+	return &ANSI{
+		scene:  color.New(color.FgWhite),
+		think:  color.New(color.FgHiBlack, color.Italic),
+		tool:   color.New(color.FgCyan),
+		dice:   color.New(color.FgYellow, color.Bold),
+		prompt: color.New(color.FgGreen, color.Bold),
+	}
+}
+
+func (r *ANSI) SceneText(text string) {
+	r.scene.Print(text)
+}
+
+func (r *ANSI) Reasoning(text string) {
+	r.think.Print(text)
+}
+
+func (r *ANSI) ToolCall(name, input string) {
+	r.tool.Printf("\n[%s] %s\n", name, input)
+}
+
+func (r *ANSI) ToolResult(_ string) {
+	r.tool.Println("-> done")
+}
+
+func (r *ANSI) PlayerPrompt(question string, options []string) {
+	r.prompt.Printf("\n\n--- YOUR TURN ---\n%s\n\n", question)
+	for i, opt := range options {
+		fmt.Printf("  %d. %s\n", i+1, opt)
+	}
+}
+
+func (r *ANSI) DiceRoll(notation string, rolls []int, total int) {
+	r.dice.Printf("Rolling %s: %v = %d\n", notation, rolls, total)
+}
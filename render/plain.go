@@ -0,0 +1,34 @@
+package render
+
+import "fmt"
+
+// Plain renders with no color or decoration — the original behavior, and
+// what --no-color or NO_COLOR fall back to.
+type Plain struct{}
+
+func (Plain) SceneText(text string) {
+	fmt.Print(text)
+}
+
+func (Plain) Reasoning(text string) {
+	fmt.Print(text)
+}
+
+func (Plain) ToolCall(name, input string) {
+	fmt.Printf("\n[%s] %s\n", name, input)
+}
+
+func (Plain) ToolResult(_ string) {
+	fmt.Println("-> done")
+}
+
+func (Plain) PlayerPrompt(question string, options []string) {
+	fmt.Printf("\n\n--- YOUR TURN ---\n%s\n\n", question)
+	for i, opt := range options {
+		fmt.Printf("  %d. %s\n", i+1, opt)
+	}
+}
+
+func (Plain) DiceRoll(notation string, rolls []int, total int) {
+	fmt.Printf("Rolling %s: %v = %d\n", notation, rolls, total)
+}
@@ -0,0 +1,34 @@
+// Package render draws DM narration, reasoning, tool activity, and dice
+// rolls to the terminal. Swapping the Renderer implementation changes how
+// the game looks without touching the game loop.
+package render
+
+import "os"
+
+// Renderer is the terminal output surface for a running game. Every stream
+// callback and the ask_player tool go through one of these methods instead
+// of printing directly, so a future TUI renderer can slot in unchanged.
+type Renderer interface {
+	// SceneText renders a chunk of DM narration as it streams in.
+	SceneText(text string)
+	// Reasoning renders a chunk of the model's visible reasoning.
+	Reasoning(text string)
+	// ToolCall renders a tool invocation and its input.
+	ToolCall(name, input string)
+	// ToolResult renders that a tool call finished.
+	ToolResult(name string)
+	// PlayerPrompt renders the question and options for the player's turn.
+	PlayerPrompt(question string, options []string)
+	// DiceRoll renders the outcome of a dice roll.
+	DiceRoll(notation string, rolls []int, total int)
+}
+
+// New returns the default color renderer, unless noColor is set or the
+// NO_COLOR environment variable (https://no-color.org) is present, in which
+// case it falls back to Plain.
+func New(noColor bool) Renderer {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return Plain{}
+	}
+	return NewANSI()
+}
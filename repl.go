@@ -0,0 +1,136 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"charm.land/fantasy"
+	"github.com/what-the-func/dnd-agent/campaign"
+	"github.com/what-the-func/dnd-agent/dice"
+)
+
+// ---------------------------------------------------------------------------
+// REPL commands
+// ---------------------------------------------------------------------------
+
+// replState is the state REPL commands read or mutate. It is shared between
+// gameLoop and the ask_player tool so a command typed mid-turn can act on the
+// same history the loop is driving.
+type replState struct {
+	campaign *campaign.State
+	history  *[]fantasy.Message
+	savePath *string
+	quit     *bool
+	retry    *bool
+}
+
+// replCommand is a slash-command the player can type instead of picking a
+// numbered option. Handlers never consume the player's turn: they print
+// local state or mutate history, and askPlayer loops back for real input.
+type replCommand struct {
+	name    string
+	usage   string
+	help    string
+	handler func(rs *replState, args string)
+}
+
+var replCommands = []replCommand{
+	{"/help", "/help", "List available commands", cmdHelp},
+	{"/inv", "/inv", "Show your inventory", cmdInventory},
+	{"/sheet", "/sheet", "Show your character sheet", cmdSheet},
+	{"/history", "/history", "Show the raw conversation history", cmdHistory},
+	{"/roll", "/roll <notation>", "Roll dice locally, e.g. /roll 2d6+3", cmdRoll},
+	{"/save", "/save [path]", "Save the campaign without quitting", cmdSave},
+	{"/retry", "/retry", "Rewind the last DM turn and re-prompt", cmdRetry},
+}
+
+// dispatchReplCommand runs the handler for the slash-command in line, or
+// prints an error if it doesn't match one.
+func dispatchReplCommand(rs *replState, line string) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return
+	}
+
+	for _, cmd := range replCommands {
+		if strings.EqualFold(fields[0], cmd.name) {
+			args := strings.TrimSpace(strings.TrimPrefix(line, fields[0]))
+			cmd.handler(rs, args)
+			return
+		}
+	}
+
+	fmt.Printf("Unknown command %q. Type /help for a list.\n", fields[0])
+}
+
+func cmdHelp(_ *replState, _ string) {
+	fmt.Println("Commands:")
+	for _, cmd := range replCommands {
+		fmt.Printf("  %-18s %s\n", cmd.usage, cmd.help)
+	}
+}
+
+func cmdInventory(rs *replState, _ string) {
+	fmt.Println(strings.Join(rs.campaign.Player.Inventory, ", "))
+}
+
+func cmdSheet(rs *replState, _ string) {
+	fmt.Println(formatPlayerState(rs.campaign))
+}
+
+func cmdHistory(rs *replState, _ string) {
+	history := *rs.history
+	if len(history) == 0 {
+		fmt.Println("No history yet.")
+		return
+	}
+	for i, msg := range history {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			fmt.Printf("%d: <unprintable message>\n", i)
+			continue
+		}
+		fmt.Printf("%d: %s\n", i, data)
+	}
+}
+
+func cmdRoll(_ *replState, args string) {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		fmt.Println("Usage: /roll <notation>, e.g. /roll 2d6+3")
+		return
+	}
+
+	result, err := dice.Roll(args)
+	if err != nil {
+		fmt.Printf("Couldn't parse %q: %v\n", args, err)
+		return
+	}
+
+	activeRenderer.DiceRoll(args, flattenRolls(result), result.Total)
+}
+
+func cmdSave(rs *replState, args string) {
+	path := strings.TrimSpace(args)
+	if path == "" {
+		path = *rs.savePath
+	}
+	if path == "" {
+		fmt.Println("No path given and no --save flag set. Usage: /save <path>")
+		return
+	}
+
+	if err := campaign.SaveCampaign(path, rs.campaign); err != nil {
+		fmt.Printf("Failed to save campaign: %v\n", err)
+		return
+	}
+
+	*rs.savePath = path
+	fmt.Printf("Campaign saved to %s.\n", path)
+}
+
+func cmdRetry(rs *replState, _ string) {
+	*rs.retry = true
+	fmt.Println("Asking the DM to retry that turn...")
+}
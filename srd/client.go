@@ -0,0 +1,132 @@
+// Package srd fetches D&D 5e SRD monster and spell data from dnd5eapi.co,
+// caching responses on disk and falling back to an embedded bundle so the
+// game keeps working with no network.
+package srd
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//go:embed fallback
+var fallbackFS embed.FS
+
+const baseURL = "https://www.dnd5eapi.co/api"
+
+// Client looks up SRD monsters and spells, in cache-then-network-then-
+// embedded order. With offline set, network requests are skipped entirely.
+type Client struct {
+	httpClient *http.Client
+	cacheDir   string
+	offline    bool
+}
+
+// NewClient returns a Client that caches to ~/.cache/dnd-agent (or the
+// platform equivalent of os.UserCacheDir).
+func NewClient(offline bool) (*Client, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, fmt.Errorf("cache dir: %w", err)
+	}
+	return &Client{
+		httpClient: http.DefaultClient,
+		cacheDir:   filepath.Join(dir, "dnd-agent"),
+		offline:    offline,
+	}, nil
+}
+
+// Monster looks up a monster by name.
+func (c *Client) Monster(ctx context.Context, name string) (map[string]any, error) {
+	return c.lookup(ctx, "monsters", name)
+}
+
+// Spell looks up a spell by name.
+func (c *Client) Spell(ctx context.Context, name string) (map[string]any, error) {
+	return c.lookup(ctx, "spells", name)
+}
+
+// Prefetch warms the monster cache for names, e.g. before a planned
+// encounter, so lookups during play don't pay per-turn network latency. It
+// is best-effort: a failure on one name doesn't stop the rest from being
+// tried, and the first error encountered (if any) is returned once all
+// names have been attempted.
+func (c *Client) Prefetch(ctx context.Context, names ...string) error {
+	var firstErr error
+	for _, name := range names {
+		if _, err := c.Monster(ctx, name); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("prefetch %s: %w", name, err)
+		}
+	}
+	return firstErr
+}
+
+func (c *Client) lookup(ctx context.Context, kind, name string) (map[string]any, error) {
+	slug := slugify(name)
+
+	if data, err := c.readCache(kind, slug); err == nil {
+		return decode(data)
+	}
+
+	if !c.offline {
+		if data, err := c.fetch(ctx, kind, slug); err == nil {
+			c.writeCache(kind, slug, data)
+			return decode(data)
+		}
+	}
+
+	data, err := fallbackFS.ReadFile(filepath.Join("fallback", kind, slug+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("%s %q not found (offline, uncached, no fallback)", strings.TrimSuffix(kind, "s"), name)
+	}
+	return decode(data)
+}
+
+func (c *Client) fetch(ctx context.Context, kind, slug string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s/%s", baseURL, kind, slug), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+func (c *Client) readCache(kind, slug string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(c.cacheDir, kind, slug+".json"))
+}
+
+func (c *Client) writeCache(kind, slug string, data []byte) {
+	dir := filepath.Join(c.cacheDir, kind)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return
+	}
+	os.WriteFile(filepath.Join(dir, slug+".json"), data, 0o644)
+}
+
+func decode(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+	return m, nil
+}
+
+func slugify(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, " ", "-"))
+}